@@ -2,21 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/metric"
-	sdklog "go.opentelemetry.io/otel/sdk/log"
-	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
-	"go.opentelemetry.io/otel/sdk/resource"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -26,91 +21,82 @@ var (
 	appLogger *slog.Logger
 )
 
-func setupInstrumentation() func() {
-	ctx := context.Background()
-	serviceName := "go-http-hello"
-
-	// Get OTLP endpoint from environment or use default
-	otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
-	if otlpEndpoint == "" {
-		otlpEndpoint = "localhost:4317"
+// setupInstrumentation wires up tracing, metrics, and logging and returns a
+// shutdown func that force-flushes and shuts down all three providers
+// concurrently against the deadline on the ctx passed to it. Callers that
+// want SIGINT/SIGTERM handling and a default shutdown timeout should use
+// RunWithInstrumentation instead of calling this directly.
+func setupInstrumentation(ctx context.Context, opts ...Option) (func(context.Context) error, error) {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
-	// Create resource
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceName(serviceName),
-			semconv.ServiceVersion("1.0.0"),
-		),
-	)
+	// instrumentationScope names the Tracer/Meter/logger for this binary; it's
+	// independent of the resource's service.name (which comes from
+	// OTEL_SERVICE_NAME or falls back to build info, see buildResource below).
+	const instrumentationScope = "go-http-hello"
+
+	// The generic OTLP endpoint, if any; each signal falls back to its own
+	// protocol's spec default (4317 for grpc, 4318 for http/*) when this and
+	// its per-signal override are both unset (see resolveEndpoint).
+	genericEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+
+	// Build the resource from the standard detectors plus k8s/cloud env vars
+	// (see buildResource in resource.go). Only pass a name/version when one
+	// is actually configured via OTEL_SERVICE_NAME, so the build-info
+	// fallback in buildResource is reachable rather than always shadowed by
+	// a literal.
+	res, err := buildResource(ctx, os.Getenv("OTEL_SERVICE_NAME"), "")
 	if err != nil {
-		slog.Error("failed to create resource", "error", err)
-		panic(err)
+		return nil, fmt.Errorf("building resource: %w", err)
 	}
 
-	// Setup tracing
-	traceExporter, err := otlptracegrpc.New(ctx,
-		otlptracegrpc.WithEndpoint(otlpEndpoint),
-		otlptracegrpc.WithInsecure(),
-	)
-	if err != nil {
-		slog.Error("failed to create trace exporter", "error", err)
-		panic(err)
+	// shutdowns accumulates the shutdown func for each provider as it's
+	// created, so that a later failure can still tear down the providers
+	// that already succeeded instead of leaking their batchers/connections.
+	var shutdowns []func(context.Context) error
+	abort := func(setupErr error) (func(context.Context) error, error) {
+		return nil, errors.Join(setupErr, combineShutdown(shutdowns...)(ctx))
 	}
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(traceExporter),
-		sdktrace.WithResource(res),
-	)
+	// Each signal resolves its own protocol, endpoint, and headers from the
+	// environment (see resolveProtocol/resolveEndpoint in exporter.go), so
+	// traces can go out over OTLP/gRPC while metrics go to stdout, etc.
+	tp, tpShutdown, err := setupTracer(ctx, res, genericEndpoint)
+	if err != nil {
+		return abort(fmt.Errorf("setting up tracer provider: %w", err))
+	}
+	shutdowns = append(shutdowns, providerShutdown(tp, tpShutdown))
 	otel.SetTracerProvider(tp)
-	appTracer = otel.Tracer(serviceName)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	appTracer = otel.Tracer(instrumentationScope)
 
-	// Setup metrics
-	metricExporter, err := otlpmetricgrpc.New(ctx,
-		otlpmetricgrpc.WithEndpoint(otlpEndpoint),
-		otlpmetricgrpc.WithInsecure(),
-	)
+	mp, mpShutdown, err := setupMeter(ctx, res, genericEndpoint)
 	if err != nil {
-		slog.Error("failed to create metric exporter", "error", err)
-		panic(err)
+		return abort(fmt.Errorf("setting up meter provider: %w", err))
 	}
-
-	mp := sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
-		sdkmetric.WithResource(res),
-	)
+	shutdowns = append(shutdowns, providerShutdown(mp, mpShutdown))
 	otel.SetMeterProvider(mp)
-	appMeter = otel.Meter(serviceName)
+	appMeter = otel.Meter(instrumentationScope)
 
-	// Setup logging
-	logExporter, err := otlploggrpc.New(ctx,
-		otlploggrpc.WithEndpoint(otlpEndpoint),
-		otlploggrpc.WithInsecure(),
-	)
-	if err != nil {
-		slog.Error("failed to create log exporter", "error", err)
-		panic(err)
+	if err := setupRuntimeHostMetrics(mp, cfg); err != nil {
+		return abort(err)
 	}
 
-	lp := sdklog.NewLoggerProvider(
-		sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter)),
-		sdklog.WithResource(res),
-	)
+	lp, lpShutdown, err := setupLogger(ctx, res, genericEndpoint)
+	if err != nil {
+		return abort(fmt.Errorf("setting up logger provider: %w", err))
+	}
+	shutdowns = append(shutdowns, providerShutdown(lp, lpShutdown))
 	global.SetLoggerProvider(lp)
 
 	// Create structured logger that will send logs to OTLP
-	otelHandler := otelslog.NewHandler("go-http-hello")
+	otelHandler := otelslog.NewHandler(instrumentationScope)
 	appLogger = slog.New(otelHandler)
 
-	return func() {
-		if err := tp.Shutdown(ctx); err != nil {
-			slog.Error("failed to shutdown tracer provider", "error", err)
-		}
-		if err := mp.Shutdown(ctx); err != nil {
-			slog.Error("failed to shutdown meter provider", "error", err)
-		}
-		if err := lp.Shutdown(ctx); err != nil {
-			slog.Error("failed to shutdown logger provider", "error", err)
-		}
-	}
-}
\ No newline at end of file
+	return combineShutdown(shutdowns...), nil
+}
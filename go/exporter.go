@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// exporterProtocol mirrors the OTEL_EXPORTER_OTLP_PROTOCOL values defined by
+// the OpenTelemetry spec.
+type exporterProtocol string
+
+const (
+	protocolGRPC         exporterProtocol = "grpc"
+	protocolHTTPProtobuf exporterProtocol = "http/protobuf"
+	protocolHTTPJSON     exporterProtocol = "http/json"
+	protocolStdout       exporterProtocol = "stdout"
+)
+
+// errHTTPJSONUnsupported is returned for OTEL_EXPORTER_OTLP_PROTOCOL=http/json:
+// the upstream Go OTLP/HTTP exporters only ever marshal protobuf, so
+// honoring "http/json" by silently sending protobuf would mislabel the wire
+// format rather than actually speaking JSON.
+var errHTTPJSONUnsupported = errors.New(`OTEL_EXPORTER_OTLP_PROTOCOL "http/json" is not supported by this exporter (only protobuf marshaling is implemented)`)
+
+// defaultEndpointFor returns the OTLP spec's default endpoint for protocol:
+// localhost:4317 for gRPC, localhost:4318 for HTTP.
+func defaultEndpointFor(protocol exporterProtocol) string {
+	switch protocol {
+	case protocolHTTPProtobuf, protocolHTTPJSON:
+		return "localhost:4318"
+	default:
+		return "localhost:4317"
+	}
+}
+
+// resolveProtocol looks up the protocol for a given signal ("TRACES",
+// "METRICS", "LOGS"), falling back to the generic OTEL_EXPORTER_OTLP_PROTOCOL
+// and finally to "grpc" when nothing is set.
+func resolveProtocol(signal string) exporterProtocol {
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_PROTOCOL"); v != "" {
+		return exporterProtocol(v)
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); v != "" {
+		return exporterProtocol(v)
+	}
+	return protocolGRPC
+}
+
+// resolveEndpoint looks up the endpoint for a given signal, falling back to
+// the generic OTEL_EXPORTER_OTLP_ENDPOINT and finally to the OTLP spec
+// default for protocol (4317 for grpc, 4318 for http/*), so switching
+// OTEL_EXPORTER_OTLP_PROTOCOL alone still dials the right port.
+func resolveEndpoint(signal string, protocol exporterProtocol, genericEndpoint string) string {
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_ENDPOINT"); v != "" {
+		return v
+	}
+	if genericEndpoint != "" {
+		return genericEndpoint
+	}
+	return defaultEndpointFor(protocol)
+}
+
+// parseHeaders parses the W3C-style "key1=value1,key2=value2" header list
+// used by OTEL_EXPORTER_OTLP_HEADERS / OTEL_EXPORTER_OTLP_<SIGNAL>_HEADERS,
+// percent-decoding each value. Uses PathUnescape rather than QueryUnescape:
+// the latter also turns "+" into a space, which would corrupt the base64
+// payload of a bearer/Basic-auth token (base64 routinely contains "+").
+func parseHeaders(raw string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(kv[0])
+		value, err := url.PathUnescape(strings.TrimSpace(kv[1]))
+		if err != nil {
+			value = strings.TrimSpace(kv[1])
+		}
+		headers[key] = value
+	}
+	return headers
+}
+
+// resolveHeaders merges the generic OTEL_EXPORTER_OTLP_HEADERS with the
+// per-signal override, the latter taking precedence key-by-key.
+func resolveHeaders(signal string) map[string]string {
+	headers := parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	for k, v := range parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_HEADERS")) {
+		headers[k] = v
+	}
+	return headers
+}
+
+// httpPath splits endpoint into its bare host:port and URL path, if any, so
+// a bare host:port passes through unchanged while a full URL (e.g.
+// "https://openobserve.example.com/api/default", or a grpc endpoint set with
+// a scheme per the OTLP spec convention) gets its scheme stripped before
+// being handed to an exporter that dials host:port directly.
+func httpPath(endpoint string) (host string, path string) {
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		return u.Host, u.Path
+	}
+	return endpoint, ""
+}
+
+// setupTracer builds a TracerProvider using the exporter selected by
+// OTEL_EXPORTER_OTLP_[TRACES_]PROTOCOL and returns it along with its
+// shutdown func. Callers are expected to call otel.SetTracerProvider
+// themselves so that tracer construction and global wiring stay separate.
+func setupTracer(ctx context.Context, res *resource.Resource, genericEndpoint string) (*sdktrace.TracerProvider, func(context.Context) error, error) {
+	exporter, err := newTraceExporter(ctx, genericEndpoint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("trace exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(newSampler()),
+	)
+	return tp, tp.Shutdown, nil
+}
+
+func newTraceExporter(ctx context.Context, genericEndpoint string) (sdktrace.SpanExporter, error) {
+	protocol := resolveProtocol("TRACES")
+	endpoint := resolveEndpoint("TRACES", protocol, genericEndpoint)
+	headers := resolveHeaders("TRACES")
+
+	switch protocol {
+	case protocolStdout:
+		return stdouttrace.New()
+	case protocolHTTPJSON:
+		return nil, errHTTPJSONUnsupported
+	case protocolHTTPProtobuf:
+		host, path := httpPath(endpoint)
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(host),
+			otlptracehttp.WithHeaders(headers),
+		}
+		if path != "" {
+			opts = append(opts, otlptracehttp.WithURLPath(path))
+		}
+		if otlpInsecure() {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else {
+			tlsCfg, err := otlpTLSConfig()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsCfg))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case protocolGRPC, "":
+		host, _ := httpPath(endpoint)
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(host),
+			otlptracegrpc.WithHeaders(headers),
+		}
+		if otlpInsecure() {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		} else {
+			creds, err := grpcTransportCredentials()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(creds))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter protocol %q", protocol)
+	}
+}
+
+// setupMeter builds a MeterProvider using the exporter selected by
+// OTEL_EXPORTER_OTLP_[METRICS_]PROTOCOL and returns it along with its
+// shutdown func.
+func setupMeter(ctx context.Context, res *resource.Resource, genericEndpoint string) (*sdkmetric.MeterProvider, func(context.Context) error, error) {
+	exporter, err := newMetricExporter(ctx, genericEndpoint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("metric exporter: %w", err)
+	}
+
+	// The Prometheus reader is additive: when OTEL_METRICS_EXPORTER asks for
+	// it, metrics get pulled from /metrics *and* pushed over OTLP from the
+	// same MeterProvider.
+	promReader, promShutdown, err := maybePrometheusReader()
+	if err != nil {
+		return nil, nil, fmt.Errorf("prometheus reader: %w", err)
+	}
+
+	opts := []sdkmetric.Option{
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithResource(res),
+		httpServerDurationView(),
+	}
+	if promReader != nil {
+		opts = append(opts, sdkmetric.WithReader(promReader))
+	}
+
+	mp := sdkmetric.NewMeterProvider(opts...)
+
+	shutdown := mp.Shutdown
+	if promShutdown != nil {
+		shutdown = func(ctx context.Context) error {
+			return errors.Join(mp.Shutdown(ctx), promShutdown(ctx))
+		}
+	}
+	return mp, shutdown, nil
+}
+
+func newMetricExporter(ctx context.Context, genericEndpoint string) (sdkmetric.Exporter, error) {
+	protocol := resolveProtocol("METRICS")
+	endpoint := resolveEndpoint("METRICS", protocol, genericEndpoint)
+	headers := resolveHeaders("METRICS")
+
+	switch protocol {
+	case protocolStdout:
+		return stdoutmetric.New()
+	case protocolHTTPJSON:
+		return nil, errHTTPJSONUnsupported
+	case protocolHTTPProtobuf:
+		host, path := httpPath(endpoint)
+		opts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(host),
+			otlpmetrichttp.WithHeaders(headers),
+		}
+		if path != "" {
+			opts = append(opts, otlpmetrichttp.WithURLPath(path))
+		}
+		if otlpInsecure() {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		} else {
+			tlsCfg, err := otlpTLSConfig()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tlsCfg))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	case protocolGRPC, "":
+		host, _ := httpPath(endpoint)
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(host),
+			otlpmetricgrpc.WithHeaders(headers),
+		}
+		if otlpInsecure() {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		} else {
+			creds, err := grpcTransportCredentials()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlpmetricgrpc.WithTLSCredentials(creds))
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported metric exporter protocol %q", protocol)
+	}
+}
+
+// setupLogger builds a LoggerProvider using the exporter selected by
+// OTEL_EXPORTER_OTLP_[LOGS_]PROTOCOL and returns it along with its
+// shutdown func.
+func setupLogger(ctx context.Context, res *resource.Resource, genericEndpoint string) (*sdklog.LoggerProvider, func(context.Context) error, error) {
+	exporter, err := newLogExporter(ctx, genericEndpoint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("log exporter: %w", err)
+	}
+
+	lp := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(res),
+	)
+	return lp, lp.Shutdown, nil
+}
+
+func newLogExporter(ctx context.Context, genericEndpoint string) (sdklog.Exporter, error) {
+	protocol := resolveProtocol("LOGS")
+	endpoint := resolveEndpoint("LOGS", protocol, genericEndpoint)
+	headers := resolveHeaders("LOGS")
+
+	switch protocol {
+	case protocolStdout:
+		return stdoutlog.New()
+	case protocolHTTPJSON:
+		return nil, errHTTPJSONUnsupported
+	case protocolHTTPProtobuf:
+		host, path := httpPath(endpoint)
+		opts := []otlploghttp.Option{
+			otlploghttp.WithEndpoint(host),
+			otlploghttp.WithHeaders(headers),
+		}
+		if path != "" {
+			opts = append(opts, otlploghttp.WithURLPath(path))
+		}
+		if otlpInsecure() {
+			opts = append(opts, otlploghttp.WithInsecure())
+		} else {
+			tlsCfg, err := otlpTLSConfig()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlploghttp.WithTLSClientConfig(tlsCfg))
+		}
+		return otlploghttp.New(ctx, opts...)
+	case protocolGRPC, "":
+		host, _ := httpPath(endpoint)
+		opts := []otlploggrpc.Option{
+			otlploggrpc.WithEndpoint(host),
+			otlploggrpc.WithHeaders(headers),
+		}
+		if otlpInsecure() {
+			opts = append(opts, otlploggrpc.WithInsecure())
+		} else {
+			creds, err := grpcTransportCredentials()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlploggrpc.WithTLSCredentials(creds))
+		}
+		return otlploggrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported log exporter protocol %q", protocol)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+func TestParseHeaders(t *testing.T) {
+	tests := map[string]struct {
+		raw  string
+		want map[string]string
+	}{
+		"single pair": {
+			raw:  "stream-name=default",
+			want: map[string]string{"stream-name": "default"},
+		},
+		"multiple pairs": {
+			raw:  "a=1,b=2",
+			want: map[string]string{"a": "1", "b": "2"},
+		},
+		"whitespace around pairs is trimmed": {
+			raw:  " a = 1 , b = 2 ",
+			want: map[string]string{"a": "1", "b": "2"},
+		},
+		"base64 value with a plus sign survives unescaped": {
+			raw:  "Authorization=Basic dXNlcjp+cGFzcw==",
+			want: map[string]string{"Authorization": "Basic dXNlcjp+cGFzcw=="},
+		},
+		"percent-encoded value is decoded": {
+			raw:  "X-Custom=hello%20world",
+			want: map[string]string{"X-Custom": "hello world"},
+		},
+		"empty string yields no headers": {
+			raw:  "",
+			want: map[string]string{},
+		},
+		"entries without an equals sign are skipped": {
+			raw:  "malformed,a=1",
+			want: map[string]string{"a": "1"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := parseHeaders(tt.raw)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseHeaders(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("parseHeaders(%q)[%q] = %q, want %q", tt.raw, k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	t.Run("per-signal override wins", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "traces.example.com:4317")
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "generic.example.com:4317")
+
+		got := resolveEndpoint("TRACES", protocolGRPC, "generic.example.com:4317")
+		if got != "traces.example.com:4317" {
+			t.Errorf("resolveEndpoint = %q, want per-signal override", got)
+		}
+	})
+
+	t.Run("generic endpoint used when no per-signal override", func(t *testing.T) {
+		got := resolveEndpoint("TRACES", protocolGRPC, "generic.example.com:4317")
+		if got != "generic.example.com:4317" {
+			t.Errorf("resolveEndpoint = %q, want generic endpoint", got)
+		}
+	})
+
+	t.Run("falls back to protocol default when nothing is set", func(t *testing.T) {
+		if got := resolveEndpoint("TRACES", protocolGRPC, ""); got != "localhost:4317" {
+			t.Errorf("resolveEndpoint(grpc) = %q, want localhost:4317", got)
+		}
+		if got := resolveEndpoint("TRACES", protocolHTTPProtobuf, ""); got != "localhost:4318" {
+			t.Errorf("resolveEndpoint(http/protobuf) = %q, want localhost:4318", got)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/host"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const defaultRuntimeMetricsInterval = 15 * time.Second
+
+// Config controls the optional pieces of setupInstrumentation that aren't
+// driven by OTEL_* env vars. Construct it via defaultConfig and the
+// With* options below.
+type Config struct {
+	runtimeMetricsInterval time.Duration
+	hostMetricsEnabled     bool
+}
+
+// Option customizes a Config.
+type Option func(*Config)
+
+func defaultConfig() Config {
+	return Config{
+		runtimeMetricsInterval: defaultRuntimeMetricsInterval,
+		hostMetricsEnabled:     true,
+	}
+}
+
+// WithRuntimeMetrics sets how often Go runtime metrics (goroutines, GC
+// pauses, heap) are sampled. Passing 0 disables runtime metrics entirely.
+func WithRuntimeMetrics(interval time.Duration) Option {
+	return func(c *Config) { c.runtimeMetricsInterval = interval }
+}
+
+// WithHostMetrics enables or disables host metrics (system.cpu.*,
+// system.memory.*). Host metrics are enabled by default.
+func WithHostMetrics(enabled bool) Option {
+	return func(c *Config) { c.hostMetricsEnabled = enabled }
+}
+
+// setupRuntimeHostMetrics registers the contrib runtime and host
+// instrumentation against mp. It's called right after the MeterProvider is
+// installed, before the periodic reader's first collection, so that first
+// export contains a full snapshot rather than a half-populated one.
+func setupRuntimeHostMetrics(mp metric.MeterProvider, cfg Config) error {
+	if cfg.runtimeMetricsInterval > 0 {
+		if err := runtime.Start(
+			runtime.WithMeterProvider(mp),
+			runtime.WithMinimumReadMemStatsInterval(cfg.runtimeMetricsInterval),
+		); err != nil {
+			return fmt.Errorf("starting runtime metrics: %w", err)
+		}
+	}
+
+	if cfg.hostMetricsEnabled {
+		if err := host.Start(host.WithMeterProvider(mp)); err != nil {
+			return fmt.Errorf("starting host metrics: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const defaultPrometheusAddr = ":9464"
+
+// prometheusEnabled reports whether OTEL_METRICS_EXPORTER lists "prometheus"
+// among its comma-separated values, letting an app push OTLP metrics and
+// expose a Prometheus pull endpoint at the same time (e.g. "otlp,prometheus").
+func prometheusEnabled() bool {
+	for _, v := range strings.Split(os.Getenv("OTEL_METRICS_EXPORTER"), ",") {
+		if strings.TrimSpace(v) == "prometheus" {
+			return true
+		}
+	}
+	return false
+}
+
+// prometheusAddr returns OTEL_PROMETHEUS_ADDR, defaulting to ":9464".
+func prometheusAddr() string {
+	if v := os.Getenv("OTEL_PROMETHEUS_ADDR"); v != "" {
+		return v
+	}
+	return defaultPrometheusAddr
+}
+
+// maybePrometheusReader builds the sdkmetric.Reader and /metrics HTTP server
+// for the Prometheus pull path when OTEL_METRICS_EXPORTER asks for it.
+// Returns a nil reader and a nil shutdown func when disabled.
+func maybePrometheusReader() (sdkmetric.Reader, func(context.Context) error, error) {
+	if !prometheusEnabled() {
+		return nil, nil, nil
+	}
+
+	reader, err := prometheus.New()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Handler: mux}
+
+	// Bind synchronously so a busy OTEL_PROMETHEUS_ADDR port surfaces as a
+	// returned error rather than only showing up once Serve runs in the
+	// background goroutine below.
+	listener, err := net.Listen("tcp", prometheusAddr())
+	if err != nil {
+		return nil, nil, fmt.Errorf("listening on %s: %w", prometheusAddr(), err)
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("prometheus metrics server stopped unexpectedly", "error", err)
+		}
+	}()
+
+	return reader, server.Shutdown, nil
+}
+
+// httpServerDurationView shapes the http.server.duration histogram with
+// buckets tuned for typical request latencies (1ms-10s) instead of the SDK's
+// generic default boundaries; add further sdkmetric.WithView calls the same
+// way to shape other instruments.
+func httpServerDurationView() sdkmetric.Option {
+	return sdkmetric.WithView(sdkmetric.NewView(
+		sdkmetric.Instrument{Name: "http.server.duration"},
+		sdkmetric.Stream{
+			Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
+				Boundaries: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			},
+		},
+	))
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// buildResource assembles the process resource from the standard detectors
+// (host, process, OS, container) plus a k8sDetector and cloud-provider
+// attributes inferred from the environment, then layers on whatever the user
+// supplied via OTEL_RESOURCE_ATTRIBUTES. Service name/version fall back to
+// build info when not explicitly set via serviceName/serviceVersion.
+func buildResource(ctx context.Context, serviceName, serviceVersion string) (*resource.Resource, error) {
+	if serviceName == "" {
+		serviceName = buildInfoServiceName()
+	}
+	if serviceVersion == "" {
+		serviceVersion = buildInfoServiceVersion()
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(serviceVersion),
+		),
+		resource.WithHost(),
+		resource.WithProcess(),
+		resource.WithOS(),
+		resource.WithContainer(),
+		resource.WithDetectors(k8sDetector{}, cloudDetector{}),
+		resource.WithFromEnv(), // OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME
+	)
+	if err != nil {
+		return nil, fmt.Errorf("detecting resource: %w", err)
+	}
+	return res, nil
+}
+
+func buildInfoServiceName() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path != "" {
+		return info.Main.Path
+	}
+	return "go-http-hello"
+}
+
+func buildInfoServiceVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "1.0.0"
+}
+
+// k8sDetector populates k8s.namespace.name and k8s.pod.name from the service
+// account namespace file and HOSTNAME, which is how the downward API
+// typically exposes pod identity without extra RBAC. It does not set
+// k8s.node.name: HOSTNAME is the pod's hostname, not the node's, and nothing
+// else here identifies the node without extra RBAC (e.g. the downward API's
+// spec.nodeName exposed via an env var).
+type k8sDetector struct{}
+
+const k8sNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+func (k8sDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	var attrs []attribute.KeyValue
+	if ns, err := os.ReadFile(k8sNamespaceFile); err == nil {
+		attrs = append(attrs, semconv.K8SNamespaceName(string(ns)))
+	}
+	if pod := os.Getenv("HOSTNAME"); pod != "" {
+		attrs = append(attrs, semconv.K8SPodName(pod))
+	}
+	if len(attrs) == 0 {
+		return resource.Empty(), nil
+	}
+	return resource.NewSchemaless(attrs...), nil
+}
+
+// cloudDetector infers the cloud provider and a couple of identifying
+// attributes purely from environment variables set by the respective
+// platforms (AWS_REGION on Lambda/ECS/EC2 user-data, GOOGLE_CLOUD_PROJECT on
+// GCE/Cloud Run/GKE), avoiding a network call to the metadata server.
+type cloudDetector struct{}
+
+func (cloudDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	var attrs []attribute.KeyValue
+	switch {
+	case os.Getenv("AWS_REGION") != "":
+		attrs = append(attrs,
+			semconv.CloudProviderAWS,
+			semconv.CloudRegion(os.Getenv("AWS_REGION")),
+		)
+	case os.Getenv("GOOGLE_CLOUD_PROJECT") != "":
+		attrs = append(attrs,
+			semconv.CloudProviderGCP,
+			semconv.CloudAccountID(os.Getenv("GOOGLE_CLOUD_PROJECT")),
+		)
+	}
+	if len(attrs) == 0 {
+		return resource.Empty(), nil
+	}
+	return resource.NewSchemaless(attrs...), nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newSampler builds the root sampler from OTEL_TRACES_SAMPLER /
+// OTEL_TRACES_SAMPLER_ARG, matching the values defined by the OpenTelemetry
+// spec plus a "rate_limited" extension for high-QPS endpoints.
+func newSampler() sdktrace.Sampler {
+	name := os.Getenv("OTEL_TRACES_SAMPLER")
+	arg := os.Getenv("OTEL_TRACES_SAMPLER_ARG")
+
+	switch name {
+	case "always_on":
+		return sdktrace.AlwaysSample()
+	case "always_off":
+		return sdktrace.NeverSample()
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(parseRatio(arg))
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(parseRatio(arg)))
+	case "rate_limited":
+		return sdktrace.ParentBased(newRateLimitedSampler(parseRate(arg), sdktrace.TraceIDRatioBased(1)))
+	case "":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	default:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	}
+}
+
+func parseRatio(arg string) float64 {
+	ratio, err := strconv.ParseFloat(arg, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		return 1
+	}
+	return ratio
+}
+
+func parseRate(arg string) float64 {
+	rate, err := strconv.ParseFloat(arg, 64)
+	if err != nil || rate <= 0 {
+		return 100
+	}
+	return rate
+}
+
+// rateLimitedSampler wraps an inner sdktrace.Sampler with a per-span-name
+// token bucket: each span name gets its own bucket refilled at ratePerSecond
+// tokens/sec, and only delegates to the inner sampler (rather than always
+// dropping) when a token is available. A sampled parent is always honored
+// via sdktrace.ParentBased, so this sampler only governs root decisions.
+type rateLimitedSampler struct {
+	rate  float64
+	inner sdktrace.Sampler
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// newRateLimitedSampler returns a sampler that allows at most ratePerSecond
+// sampled traces per second for each distinct span name, delegating the
+// actual sampling decision to inner once a token is available.
+func newRateLimitedSampler(ratePerSecond float64, inner sdktrace.Sampler) sdktrace.Sampler {
+	return &rateLimitedSampler{
+		rate:    ratePerSecond,
+		inner:   inner,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+func (s *rateLimitedSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if !s.bucketFor(p.Name).take() {
+		// Propagate the parent's Tracestate even on Drop, matching the
+		// SDK's built-in samplers (e.g. TraceIDRatioBased).
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.Drop,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+
+	result := s.inner.ShouldSample(p)
+	if result.Decision == sdktrace.Drop {
+		return result
+	}
+	result.Decision = sdktrace.RecordAndSample
+	return result
+}
+
+func (s *rateLimitedSampler) Description() string {
+	return fmt.Sprintf("RateLimitedSampler{rate=%v,inner=%s}", s.rate, s.inner.Description())
+}
+
+func (s *rateLimitedSampler) bucketFor(name string) *tokenBucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[name]
+	if !ok {
+		b = newTokenBucket(s.rate)
+		s.buckets[name] = b
+	}
+	return b
+}
+
+// tokenBucket is a minimal token-bucket rate limiter: capacity tokens,
+// refilled continuously at ratePerSecond tokens/sec. Capacity is
+// ratePerSecond but never less than one token, so a fractional rate still
+// admits one span every 1/ratePerSecond seconds.
+type tokenBucket struct {
+	ratePerSecond float64
+	capacity      float64
+
+	mu       sync.Mutex
+	tokens   float64
+	lastFill time.Time
+}
+
+func newTokenBucket(ratePerSecond float64) *tokenBucket {
+	capacity := max(ratePerSecond, 1)
+	return &tokenBucket{
+		ratePerSecond: ratePerSecond,
+		capacity:      capacity,
+		tokens:        capacity,
+		lastFill:      time.Now(),
+	}
+}
+
+func (b *tokenBucket) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(b.lastFill).Seconds()
+	b.lastFill = now
+	b.tokens = min(b.capacity, b.tokens+elapsed*b.ratePerSecond)
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
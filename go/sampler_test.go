@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(10)
+
+	for i := 0; i < 10; i++ {
+		if !b.take() {
+			t.Fatalf("take() #%d = false, want true (bucket starts full)", i)
+		}
+	}
+	if b.take() {
+		t.Fatal("take() = true after exhausting the bucket, want false")
+	}
+}
+
+func TestTokenBucketRefills(t *testing.T) {
+	b := newTokenBucket(1000) // fast refill so the test doesn't sleep long
+	for b.take() {
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if !b.take() {
+		t.Fatal("take() = false after waiting for a refill, want true")
+	}
+}
+
+func TestTokenBucketConcurrentTakeNeverExceedsCapacity(t *testing.T) {
+	b := newTokenBucket(50)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	granted := 0
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b.take() {
+				mu.Lock()
+				granted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if granted > 50 {
+		t.Errorf("granted %d tokens from a 50-token bucket with no elapsed time, want <= 50", granted)
+	}
+}
+
+func TestRateLimitedSamplerDropsOnceExhausted(t *testing.T) {
+	s := newRateLimitedSampler(1, sdktrace.AlwaysSample())
+	params := sdktrace.SamplingParameters{ParentContext: context.Background(), Name: "span"}
+
+	first := s.ShouldSample(params)
+	if first.Decision != sdktrace.RecordAndSample {
+		t.Fatalf("first ShouldSample = %v, want RecordAndSample", first.Decision)
+	}
+
+	second := s.ShouldSample(params)
+	if second.Decision != sdktrace.Drop {
+		t.Fatalf("second ShouldSample = %v, want Drop", second.Decision)
+	}
+}
+
+func TestRateLimitedSamplerIsolatesBucketsPerSpanName(t *testing.T) {
+	s := newRateLimitedSampler(1, sdktrace.AlwaysSample())
+	ctx := context.Background()
+
+	if got := s.ShouldSample(sdktrace.SamplingParameters{ParentContext: ctx, Name: "a"}).Decision; got != sdktrace.RecordAndSample {
+		t.Fatalf("span a first ShouldSample = %v, want RecordAndSample", got)
+	}
+	if got := s.ShouldSample(sdktrace.SamplingParameters{ParentContext: ctx, Name: "b"}).Decision; got != sdktrace.RecordAndSample {
+		t.Fatalf("span b first ShouldSample = %v, want RecordAndSample (separate bucket from a)", got)
+	}
+}
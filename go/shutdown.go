@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultShutdownTimeout = 10 * time.Second
+
+// flusher is satisfied by all three provider types (tracer, meter, logger);
+// they each expose ForceFlush so buffered telemetry is pushed out before the
+// providers themselves shut down.
+type flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// shutdownTimeout returns OTEL_SHUTDOWN_TIMEOUT, defaulting to 10s when unset
+// or invalid.
+func shutdownTimeout() time.Duration {
+	v := os.Getenv("OTEL_SHUTDOWN_TIMEOUT")
+	if v == "" {
+		return defaultShutdownTimeout
+	}
+	ms, err := strconv.Atoi(v)
+	if err != nil || ms <= 0 {
+		return defaultShutdownTimeout
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// providerShutdown combines a provider's ForceFlush and Shutdown into the
+// single func(context.Context) error this package threads around.
+func providerShutdown(p flusher, shutdown func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return errors.Join(p.ForceFlush(ctx), shutdown(ctx))
+	}
+}
+
+// combineShutdown runs each shutdown func concurrently against the deadline
+// on ctx, aggregating their errors with errors.Join.
+func combineShutdown(shutdowns ...func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		errs := make([]error, len(shutdowns))
+		var wg sync.WaitGroup
+		wg.Add(len(shutdowns))
+		for i, s := range shutdowns {
+			go func(i int, s func(context.Context) error) {
+				defer wg.Done()
+				errs[i] = s(ctx)
+			}(i, s)
+		}
+		wg.Wait()
+		return errors.Join(errs...)
+	}
+}
+
+// RunWithInstrumentation sets up instrumentation, runs fn with a context
+// that's canceled on SIGINT/SIGTERM, and shuts instrumentation down with a
+// deadline of OTEL_SHUTDOWN_TIMEOUT (default 10s) regardless of how fn
+// returns. The shutdown and fn errors are combined with errors.Join.
+func RunWithInstrumentation(ctx context.Context, fn func(context.Context) error, opts ...Option) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdown, err := setupInstrumentation(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	runErr := fn(ctx)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+	defer cancel()
+	return errors.Join(runErr, shutdown(shutdownCtx))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// otlpTLSConfig builds a *tls.Config from the standard OTLP TLS env vars:
+//
+//	OTEL_EXPORTER_OTLP_CERTIFICATE         - PEM file of CA certs to trust
+//	OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE  - PEM file of the client cert
+//	OTEL_EXPORTER_OTLP_CLIENT_KEY          - PEM file of the client key
+//
+// The client cert/key pair is loaded through GetClientCertificate so that a
+// certificate rotated on disk (e.g. by a sidecar) is picked up on the next
+// handshake rather than only at process start.
+func otlpTLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{}
+
+	if caFile := os.Getenv("OTEL_EXPORTER_OTLP_CERTIFICATE"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading OTEL_EXPORTER_OTLP_CERTIFICATE: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in OTEL_EXPORTER_OTLP_CERTIFICATE %q", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	certFile := os.Getenv("OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE")
+	keyFile := os.Getenv("OTEL_EXPORTER_OTLP_CLIENT_KEY")
+	if certFile != "" && keyFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("loading client certificate: %w", err)
+			}
+			return &cert, nil
+		}
+	}
+
+	return cfg, nil
+}
+
+// otlpInsecure reports whether the OTLP exporters should skip TLS. It honors
+// an explicit OTEL_EXPORTER_OTLP_INSECURE first; otherwise it defaults to
+// insecure (plaintext) unless TLS material has actually been configured,
+// matching this repo's examples, which talk to a local, plaintext collector
+// out of the box.
+func otlpInsecure() bool {
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_INSECURE"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return !tlsMaterialConfigured()
+}
+
+// tlsMaterialConfigured reports whether any of the OTLP TLS env vars that
+// otlpTLSConfig reads have been set.
+func tlsMaterialConfigured() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_CERTIFICATE") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_CLIENT_KEY") != ""
+}
+
+// grpcTransportCredentials returns the gRPC transport credentials built from
+// the OTLP TLS env vars above. Callers are expected to check otlpInsecure()
+// first and skip TLS entirely when it's set.
+func grpcTransportCredentials() (credentials.TransportCredentials, error) {
+	tlsCfg, err := otlpTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}